@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/sheets/v4"
+)
+
+const sheetsScope = "https://www.googleapis.com/auth/spreadsheets"
+
+// googleSheetsService builds an authenticated Sheets client. It first tries
+// the credentials file as a service account (for headless/CI use), then
+// falls back to the interactive, user-consent OAuth flow.
+func googleSheetsService(ctx context.Context) (*sheets.Service, error) {
+	b, err := ioutil.ReadFile(credentialsFilePath())
+	if err != nil {
+		return nil, err
+	}
+	if jwtConfig, jerr := google.JWTConfigFromJSON(b, sheetsScope); jerr == nil {
+		return sheets.New(jwtConfig.Client(ctx))
+	}
+	// If modifying the scope above, delete your previously saved token file.
+	config, err := google.ConfigFromJSON(b, sheetsScope)
+	if err != nil {
+		return nil, err
+	}
+	tok, err := getOauthToken(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	src := &savingTokenSource{TokenSource: config.TokenSource(ctx, tok)}
+	return sheets.New(oauth2.NewClient(ctx, src))
+}
+
+// credentialsFilePath returns the path to the credentials file, honoring
+// GOOGLE_APPLICATION_CREDENTIALS (the same env var the Google client
+// libraries use) over the -credentials flag, so the tool can run headless
+// in CI without any flag changes.
+func credentialsFilePath() string {
+	if path := os.Getenv("GOOGLE_APPLICATION_CREDENTIALS"); path != "" {
+		return path
+	}
+	return *credentialsFile
+}
+
+// tokenFilePath returns the path to the cached OAuth token, honoring
+// GOOGLE_AUTH_TOKEN_FILE over the -token flag.
+func tokenFilePath() string {
+	if path := os.Getenv("GOOGLE_AUTH_TOKEN_FILE"); path != "" {
+		return path
+	}
+	return *tokenFile
+}
+
+// getOauthToken returns a cached token, if one exists, or otherwise runs
+// the interactive OAuth flow to obtain and cache one.
+func getOauthToken(ctx context.Context, config *oauth2.Config) (*oauth2.Token, error) {
+	// The token file stores the user's access and refresh tokens, and is
+	// created automatically when the authorization flow completes for the
+	// first time.
+	f, err := os.Open(tokenFilePath())
+	if err == nil {
+		defer f.Close()
+		tok := &oauth2.Token{}
+		if err := json.NewDecoder(f).Decode(tok); err != nil {
+			return nil, err
+		}
+		return tok, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	code, err := requestAuthCode(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	tok, err := config.Exchange(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if err := saveToken(tok); err != nil {
+		return nil, err
+	}
+	return tok, nil
+}
+
+// requestAuthCode drives the user through the OAuth consent screen via a
+// local HTTP loopback listener: it points the OAuth redirect at
+// 127.0.0.1:<random port>, opens the consent screen in the user's browser
+// (best-effort), and returns the "code" query parameter from the resulting
+// callback.
+func requestAuthCode(ctx context.Context, config *oauth2.Config) (string, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", err
+	}
+	defer listener.Close()
+	config.RedirectURL = fmt.Sprintf("http://%s/callback", listener.Addr())
+
+	codeCh := make(chan string, 1)
+	errCh := make(chan error, 1)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/callback", func(w http.ResponseWriter, r *http.Request) {
+		if msg := r.URL.Query().Get("error"); msg != "" {
+			errCh <- fmt.Errorf("authorization failed: %s", msg)
+			fmt.Fprintln(w, "Authorization failed; you may close this tab.")
+			return
+		}
+		code := r.URL.Query().Get("code")
+		if code == "" {
+			errCh <- fmt.Errorf("callback request had no code")
+			return
+		}
+		codeCh <- code
+		fmt.Fprintln(w, "Authorization complete; you may close this tab.")
+	})
+	srv := &http.Server{Handler: mux}
+	go srv.Serve(listener)
+	defer srv.Close()
+
+	authURL := config.AuthCodeURL("state-token", oauth2.AccessTypeOffline)
+	fmt.Printf("Opening your browser to authorize work-stats. If it doesn't open, visit:\n%s\n", authURL)
+	if err := openBrowser(authURL); err != nil {
+		log.Printf("couldn't open browser automatically: %v", err)
+	}
+
+	select {
+	case code := <-codeCh:
+		return code, nil
+	case err := <-errCh:
+		return "", err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// openBrowser best-effort opens url in the user's default browser.
+func openBrowser(url string) error {
+	switch runtime.GOOS {
+	case "windows":
+		return exec.Command("cmd", "/c", "start", url).Start()
+	case "darwin":
+		return exec.Command("open", url).Start()
+	default:
+		return exec.Command("xdg-open", url).Start()
+	}
+}
+
+// saveToken writes tok to the token file for future use.
+func saveToken(tok *oauth2.Token) error {
+	path := tokenFilePath()
+	fmt.Printf("Saving credential file to: %s\n", path)
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return json.NewEncoder(f).Encode(tok)
+}
+
+// savingTokenSource wraps an oauth2.TokenSource, rewriting the token file
+// whenever the wrapped source returns a refreshed token. Without this, a
+// refreshed access token and expiry are only ever held in memory, and the
+// next run starts from the stale token in the token file.
+type savingTokenSource struct {
+	oauth2.TokenSource
+	last *oauth2.Token
+}
+
+func (s *savingTokenSource) Token() (*oauth2.Token, error) {
+	tok, err := s.TokenSource.Token()
+	if err != nil {
+		return nil, err
+	}
+	if s.last == nil || tok.AccessToken != s.last.AccessToken || !tok.Expiry.Equal(s.last.Expiry) {
+		if err := saveToken(tok); err != nil {
+			log.Printf("couldn't persist refreshed token: %v", err)
+		}
+		s.last = tok
+	}
+	return tok, nil
+}
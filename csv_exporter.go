@@ -0,0 +1,48 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// csvExporter writes each sheet to its own "<title>.csv" file in dir,
+// exactly as write always has.
+type csvExporter struct {
+	dir    string
+	file   *os.File
+	writer *csv.Writer
+	path   string
+}
+
+func newCSVExporter(dir string) *csvExporter {
+	return &csvExporter{dir: dir}
+}
+
+func (e *csvExporter) Begin(title string) error {
+	e.path = filepath.Join(e.dir, fmt.Sprintf("%s.csv", title))
+	file, err := os.Create(e.path)
+	if err != nil {
+		return err
+	}
+	e.file = file
+	e.writer = csv.NewWriter(file)
+	return nil
+}
+
+func (e *csvExporter) Row(cells []string, style RowStyle) error {
+	return e.writer.Write(cells)
+}
+
+func (e *csvExporter) End() error {
+	e.writer.Flush()
+	if err := e.writer.Error(); err != nil {
+		return err
+	}
+	if err := e.file.Close(); err != nil {
+		return err
+	}
+	fmt.Printf("Wrote output to %s.\n", e.path)
+	return nil
+}
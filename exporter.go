@@ -0,0 +1,220 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+// RowStyle describes the visual treatment a row should get, independent of
+// which Exporter ends up rendering it. It carries the same bold/total/
+// subtotal semantics write has always derived from a row's first cell, so
+// every exporter can apply equivalent styling without re-deriving it.
+type RowStyle int
+
+const (
+	// RowNormal is an ordinary data row.
+	RowNormal RowStyle = iota
+	// RowHeader is a sheet's header row (always the first row written).
+	RowHeader
+	// RowSubtotal is a per-contributor subtotal row, as produced by rollup.
+	RowSubtotal
+	// RowTotal is a sheet's trailing grand-total row.
+	RowTotal
+)
+
+// styleForRow derives the RowStyle for a row at index i (0 being the
+// header), using the same "Total"/"Subtotal" first-cell convention that
+// write has always used to decide what to bold and shade.
+func styleForRow(i int, row []string) RowStyle {
+	if i == 0 {
+		return RowHeader
+	}
+	if len(row) > 0 {
+		switch row[0] {
+		case "Total":
+			return RowTotal
+		case "Subtotal":
+			return RowSubtotal
+		}
+	}
+	return RowNormal
+}
+
+// Exporter is a pluggable output backend: a sheet is written by calling
+// Begin with its title, Row once per row (in order, header first), and End
+// when the sheet is complete. Implementations that batch their output
+// across sheets (e.g. xlsx, which writes a single file) can also implement
+// Flusher.
+type Exporter interface {
+	Begin(title string) error
+	Row(cells []string, style RowStyle) error
+	End() error
+}
+
+// Flusher is implemented by exporters that need a final step once every
+// sheet has been written, such as saving a workbook file to disk.
+type Flusher interface {
+	Flush() error
+}
+
+// exportAll feeds every sheet in data through exp, in the same
+// Begin/Row.../End sequence regardless of which Exporter is in use. A run
+// typically calls exportAll many times (once per contributor, per mode,
+// ...) against the same exporters, so flushing is a separate step; see
+// flushAll.
+func exportAll(exp Exporter, data map[string][][]string) error {
+	for title, rows := range data {
+		if err := exp.Begin(title); err != nil {
+			return err
+		}
+		for i, row := range rows {
+			if err := exp.Row(row, styleForRow(i, row)); err != nil {
+				return err
+			}
+		}
+		if err := exp.End(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// write feeds data through every exporter in exps.
+func write(data map[string][][]string, exps []Exporter) error {
+	for _, exp := range exps {
+		if err := exportAll(exp, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// flushAll calls Flush on every exporter in exps that supports it, once
+// the run has finished writing. Exporters that batch their output across
+// sheets (xlsx, ndjson) rely on this to produce their final file.
+func flushAll(exps []Exporter) error {
+	for _, exp := range exps {
+		if f, ok := exp.(Flusher); ok {
+			if err := f.Flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// outputSpec is one parsed "-out backend:destination" entry.
+type outputSpec struct {
+	backend     string
+	destination string
+}
+
+// parseOutputSpec parses a single -out flag value, e.g. "csv:./out",
+// "xlsx:./stats.xlsx", or "sheets:new". A spec with no ":" is taken to be a
+// bare backend name with no destination (e.g. "json").
+func parseOutputSpec(spec string) (outputSpec, error) {
+	backend, destination := spec, ""
+	if i := strings.Index(spec, ":"); i >= 0 {
+		backend, destination = spec[:i], spec[i+1:]
+	}
+	backend = strings.TrimSpace(backend)
+	if backend == "" {
+		return outputSpec{}, fmt.Errorf("invalid -out %q: want backend:destination", spec)
+	}
+	return outputSpec{backend: backend, destination: destination}, nil
+}
+
+// outFlag is a repeatable -out flag: each occurrence appends a new
+// "backend:destination" spec instead of overwriting the previous one, the
+// way flag.String would.
+type outFlag []string
+
+func (f *outFlag) String() string {
+	if f == nil {
+		return ""
+	}
+	return strings.Join(*f, ",")
+}
+
+func (f *outFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+// sheetsDestFromOutFlags scans the -out flag for a "sheets:" entry, so that
+// main can fold it into the Google Sheets target instead of treating it as
+// a no-op. ok is false if outFlags has no "sheets" entry at all.
+func sheetsDestFromOutFlags() (dest string, ok bool, err error) {
+	for _, raw := range outFlags {
+		spec, err := parseOutputSpec(raw)
+		if err != nil {
+			return "", false, err
+		}
+		if spec.backend == "sheets" {
+			return spec.destination, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+// buildExporters resolves the -out flag (defaulting to a single CSV
+// exporter writing to dir, matching the tool's historical behavior) into
+// the concrete Exporter instances for a run. The Sheets exporter is always
+// included, since rowData is always built and main decides separately
+// (via sheetsDestFromOutFlags) whether it actually writes rowData to
+// Google Sheets; a "sheets:" -out entry doesn't add a second Exporter
+// here, since it's handled by main instead.
+func buildExporters(dir string, rowData map[string][]*sheets.RowData) ([]Exporter, error) {
+	specs := []outputSpec{{backend: "csv"}}
+	if len(outFlags) > 0 {
+		specs = nil
+		for _, raw := range outFlags {
+			spec, err := parseOutputSpec(raw)
+			if err != nil {
+				return nil, err
+			}
+			specs = append(specs, spec)
+		}
+	}
+
+	exps := []Exporter{newSheetsExporter(rowData)}
+	for _, spec := range specs {
+		dest := spec.destination
+		switch spec.backend {
+		case "csv":
+			if dest == "" {
+				dest = dir
+			}
+			exps = append(exps, newCSVExporter(dest))
+		case "md", "markdown":
+			if dest == "" {
+				dest = dir
+			}
+			exps = append(exps, newMarkdownExporter(dest))
+		case "json":
+			if dest == "" {
+				dest = dir
+			}
+			exps = append(exps, newJSONExporter(dest))
+		case "xlsx":
+			if dest == "" {
+				dest = filepath.Join(dir, "stats.xlsx")
+			}
+			exp, err := newXLSXExporter(dest)
+			if err != nil {
+				return nil, err
+			}
+			exps = append(exps, exp)
+		case "sheets":
+			// main resolves the Sheets target from this entry or -sheets;
+			// the sheetsExporter above already builds its rowData, so
+			// there's nothing more to add.
+		default:
+			return nil, fmt.Errorf("unknown -out backend %q", spec.backend)
+		}
+	}
+	return exps, nil
+}
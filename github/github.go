@@ -0,0 +1,281 @@
+// Package github computes a contributor's activity on GitHub issues and
+// pull requests. IssuesAndPRs leaves out the golang/go repository, which is
+// covered instead by the golang package using the maintner corpus.
+package github
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v28/github"
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+)
+
+// newClient returns a GitHub API client, authenticated with the token in
+// the GITHUB_TOKEN environment variable, if set. Search queries against
+// the public API are rate-limited much more aggressively when
+// unauthenticated.
+func newClient(ctx context.Context) *github.Client {
+	token := os.Getenv("GITHUB_TOKEN")
+	if token == "" {
+		return github.NewClient(nil)
+	}
+	ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})
+	return github.NewClient(oauth2.NewClient(ctx, ts))
+}
+
+// IssuesAndPRs returns the GitHub issues and pull requests that the given
+// user has authored in [start, until), across all of GitHub except
+// golang/go, one sheet per repository, rendered as rows ready to be passed
+// to the CSV/Sheets writer. A zero until means no upper bound.
+func IssuesAndPRs(ctx context.Context, username string, start, until time.Time) (map[string][][]string, error) {
+	client := newClient(ctx)
+	dateRange := fmt.Sprintf(">=%s", start.Format("2006-01-02"))
+	if !until.IsZero() {
+		dateRange = fmt.Sprintf("%s..%s", start.Format("2006-01-02"), until.Format("2006-01-02"))
+	}
+	query := fmt.Sprintf("author:%s created:%s", username, dateRange)
+
+	data := make(map[string][][]string)
+	opt := &github.SearchOptions{
+		Sort:        "created",
+		Order:       "asc",
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	for {
+		result, resp, err := client.Search.Issues(ctx, query, opt)
+		if err != nil {
+			return nil, errors.Wrap(err, "searching GitHub issues")
+		}
+		for _, issue := range result.Issues {
+			repo := repoFromHTMLURL(issue.GetHTMLURL())
+			if repo == "" || repo == "golang/go" {
+				continue
+			}
+			kind := "Issue"
+			if issue.IsPullRequest() {
+				kind = "Pull Request"
+			}
+			status := "Open"
+			if issue.GetState() == "closed" {
+				status = "Closed"
+			}
+			if _, ok := data[repo]; !ok {
+				data[repo] = [][]string{
+					{"Status", "Type", "Link", "Title", "Created"},
+				}
+			}
+			data[repo] = append(data[repo], []string{
+				status,
+				kind,
+				issue.GetHTMLURL(),
+				issue.GetTitle(),
+				issue.GetCreatedAt().Format("2006-01-02"),
+			})
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+
+	for repo, rows := range data {
+		sort.Slice(rows[1:], func(i, j int) bool {
+			return rows[1:][i][4] < rows[1:][j][4]
+		})
+		data[repo] = append(rows, []string{"Total", "", "", "", fmt.Sprintf("%d", len(rows)-1)})
+	}
+	return data, nil
+}
+
+// firstResponseBucket accumulates the issues considered and their response
+// latencies for a single ISO week, so FirstResponseTime can report medians
+// and percentiles per week as well as overall.
+type firstResponseBucket struct {
+	considered int
+	durations  []time.Duration
+}
+
+// FirstResponseTime reports the median and 95th-percentile time between
+// creation and first comment from someone other than the issue's author,
+// for the issues across all of GitHub that username opened or was
+// assigned to in [start, until), bucketed by the issue's creation ISO
+// week, mirroring golang.ReviewLatency. A zero until means no upper
+// bound.
+func FirstResponseTime(ctx context.Context, username string, start, until time.Time) (map[string][][]string, error) {
+	client := newClient(ctx)
+	dateRange := fmt.Sprintf(">=%s", start.Format("2006-01-02"))
+	if !until.IsZero() {
+		dateRange = fmt.Sprintf("%s..%s", start.Format("2006-01-02"), until.Format("2006-01-02"))
+	}
+
+	// An issue the user opened and an issue they were merely assigned to
+	// are both in scope, but involves: is broader still, also matching
+	// issues the user only commented on or was mentioned in; search
+	// author: and assignee: separately and dedupe the union by URL, since
+	// an issue the user opened and was also assigned could otherwise be
+	// counted twice.
+	seen := make(map[string]*github.Issue)
+	for _, qualifier := range []string{"author", "assignee"} {
+		query := fmt.Sprintf("%s:%s is:issue created:%s", qualifier, username, dateRange)
+		opt := &github.SearchOptions{
+			Sort:        "created",
+			Order:       "asc",
+			ListOptions: github.ListOptions{PerPage: 100},
+		}
+		for {
+			result, resp, err := client.Search.Issues(ctx, query, opt)
+			if err != nil {
+				return nil, errors.Wrap(err, "searching GitHub issues")
+			}
+			for _, issue := range result.Issues {
+				issue := issue
+				seen[issue.GetHTMLURL()] = &issue
+			}
+			if resp.NextPage == 0 {
+				break
+			}
+			opt.Page = resp.NextPage
+		}
+	}
+	var urls []string
+	for url := range seen {
+		urls = append(urls, url)
+	}
+	sort.Strings(urls)
+
+	buckets := make(map[string]*firstResponseBucket)
+	grand := &firstResponseBucket{}
+	for _, url := range urls {
+		issue := seen[url]
+		repo := repoFromHTMLURL(issue.GetHTMLURL())
+		if repo == "" {
+			continue
+		}
+		d, err := firstResponseLatency(ctx, client, repo, issue)
+		if err != nil {
+			return nil, err
+		}
+		year, week := issue.GetCreatedAt().ISOWeek()
+		key := fmt.Sprintf("%d-W%02d", year, week)
+		b, ok := buckets[key]
+		if !ok {
+			b = &firstResponseBucket{}
+			buckets[key] = b
+		}
+		b.considered++
+		grand.considered++
+		if d >= 0 {
+			b.durations = append(b.durations, d)
+			grand.durations = append(grand.durations, d)
+		}
+	}
+
+	var weeks []string
+	for week := range buckets {
+		weeks = append(weeks, week)
+	}
+	sort.Strings(weeks)
+
+	rows := [][]string{
+		{"Week", "Issues Considered", "Issues With a Response", "Median Time to First Response", "95th Percentile Time to First Response"},
+	}
+	for _, week := range weeks {
+		b := buckets[week]
+		sort.Slice(b.durations, func(i, j int) bool { return b.durations[i] < b.durations[j] })
+		rows = append(rows, []string{
+			week,
+			fmt.Sprintf("%d", b.considered),
+			fmt.Sprintf("%d", len(b.durations)),
+			durationAt(b.durations, 0.5),
+			durationAt(b.durations, 0.95),
+		})
+	}
+	sort.Slice(grand.durations, func(i, j int) bool { return grand.durations[i] < grand.durations[j] })
+	rows = append(rows, []string{
+		"Total",
+		fmt.Sprintf("%d", grand.considered),
+		fmt.Sprintf("%d", len(grand.durations)),
+		durationAt(grand.durations, 0.5),
+		durationAt(grand.durations, 0.95),
+	})
+	return map[string][][]string{"Issue Response Time": rows}, nil
+}
+
+// firstResponseLatency returns the time between issue's creation and its
+// first comment from someone other than its author, or -1 if it has none.
+func firstResponseLatency(ctx context.Context, client *github.Client, repo string, issue *github.Issue) (time.Duration, error) {
+	parts := strings.SplitN(repo, "/", 2)
+	if len(parts) != 2 {
+		return -1, nil
+	}
+	owner, name := parts[0], parts[1]
+	author := issue.GetUser().GetLogin()
+
+	opt := &github.IssueListCommentsOptions{
+		Sort:        "created",
+		Direction:   "asc",
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	for {
+		comments, resp, err := client.Issues.ListComments(ctx, owner, name, issue.GetNumber(), opt)
+		if err != nil {
+			return -1, errors.Wrap(err, "listing issue comments")
+		}
+		for _, c := range comments {
+			if c.GetUser().GetLogin() == author {
+				continue
+			}
+			return c.GetCreatedAt().Sub(issue.GetCreatedAt()), nil
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+	return -1, nil
+}
+
+// durationAt returns the duration at the given percentile (0 to 1) of the
+// sorted durations, using the nearest-rank method, or "n/a" if durations is
+// empty.
+func durationAt(sorted []time.Duration, percentile float64) string {
+	if len(sorted) == 0 {
+		return "n/a"
+	}
+	idx := int(math.Ceil(percentile*float64(len(sorted)))) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= len(sorted) {
+		idx = len(sorted) - 1
+	}
+	return sorted[idx].Round(time.Hour).String()
+}
+
+// repoFromHTMLURL extracts the "owner/repo" portion of a GitHub issue or
+// pull request HTML URL, e.g.
+// "https://github.com/golang/go/issues/123" -> "golang/go".
+func repoFromHTMLURL(htmlURL string) string {
+	const prefix = "https://github.com/"
+	if len(htmlURL) <= len(prefix) || htmlURL[:len(prefix)] != prefix {
+		return ""
+	}
+	rest := htmlURL[len(prefix):]
+	var slashes int
+	for i, c := range rest {
+		if c == '/' {
+			slashes++
+			if slashes == 2 {
+				return rest[:i]
+			}
+		}
+	}
+	return ""
+}
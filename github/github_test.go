@@ -0,0 +1,55 @@
+package github
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDurationAt(t *testing.T) {
+	sorted := []time.Duration{
+		1 * time.Hour,
+		2 * time.Hour,
+		3 * time.Hour,
+		4 * time.Hour,
+	}
+	tests := []struct {
+		name       string
+		sorted     []time.Duration
+		percentile float64
+		want       string
+	}{
+		{"empty", nil, 0.5, "n/a"},
+		{"median", sorted, 0.5, "2h0m0s"},
+		{"95th percentile", sorted, 0.95, "4h0m0s"},
+		{"0th percentile clamps to first", sorted, 0, "1h0m0s"},
+		{"100th percentile is the last", sorted, 1, "4h0m0s"},
+		{"single sample", []time.Duration{90 * time.Minute}, 0.5, "2h0m0s"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := durationAt(tt.sorted, tt.percentile); got != tt.want {
+				t.Errorf("durationAt(%v, %v) = %q, want %q", tt.sorted, tt.percentile, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRepoFromHTMLURL(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{"issue URL", "https://github.com/golang/go/issues/123", "golang/go"},
+		{"pull request URL", "https://github.com/stamblerre/work-stats/pull/4", "stamblerre/work-stats"},
+		{"not a GitHub URL", "https://example.com/golang/go/issues/123", ""},
+		{"missing repo", "https://github.com/golang", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := repoFromHTMLURL(tt.url); got != tt.want {
+				t.Errorf("repoFromHTMLURL(%q) = %q, want %q", tt.url, got, tt.want)
+			}
+		})
+	}
+}
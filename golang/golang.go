@@ -0,0 +1,406 @@
+// Package golang computes a contributor's activity on the Go project:
+// issues filed through GitHub, and changelists sent
+// through Gerrit code review, as seen by the golang.org/x/build/maintner
+// corpus.
+package golang
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/build/maintner"
+)
+
+// goGitHubOwner and goGitHubRepo identify the golang/go repository, which
+// is where the Go project tracks issues (code review happens on Gerrit,
+// not through GitHub pull requests).
+const (
+	goGitHubOwner = "golang"
+	goGitHubRepo  = "go"
+)
+
+// Issues returns the GitHub issues in golang/go that the given user filed
+// in [start, until), rendered as rows ready to be passed to the CSV/Sheets
+// writer. A zero until means no upper bound.
+func Issues(corpus *maintner.GitHub, username string, start, until time.Time) (map[string][][]string, error) {
+	if corpus == nil {
+		return nil, errors.New("no GitHub corpus available")
+	}
+	repo := corpus.Repo(goGitHubOwner, goGitHubRepo)
+	if repo == nil {
+		return nil, errors.Errorf("no data for %s/%s", goGitHubOwner, goGitHubRepo)
+	}
+	rows := [][]string{
+		{"Status", "Issue", "Title", "Created"},
+	}
+	var total int
+	if err := repo.ForeachIssue(func(issue *maintner.GitHubIssue) error {
+		if issue.PullRequest || issue.NotExist {
+			return nil
+		}
+		if issue.User == nil || issue.User.Login != username {
+			return nil
+		}
+		if issue.Created.Before(start) {
+			return nil
+		}
+		if !until.IsZero() && !issue.Created.Before(until) {
+			return nil
+		}
+		status := "Open"
+		if issue.Closed {
+			status = "Closed"
+		}
+		rows = append(rows, []string{
+			status,
+			fmt.Sprintf("https://golang.org/issue/%d", issue.Number),
+			issue.Title,
+			issue.Created.Format("2006-01-02"),
+		})
+		total++
+		return nil
+	}); err != nil {
+		return nil, errors.Wrap(err, "iterating golang/go issues")
+	}
+	sort.Slice(rows[1:], func(i, j int) bool {
+		return rows[1:][i][3] < rows[1:][j][3]
+	})
+	rows = append(rows, []string{"Total", "", "", fmt.Sprintf("%d", total)})
+	return map[string][][]string{"Go Issues": rows}, nil
+}
+
+// Changelists returns the Gerrit CLs sent by any of the given emails in
+// [start, until), one sheet per Gerrit project, rendered as rows ready to
+// be passed to the CSV/Sheets writer. A zero until means no upper bound.
+// If projects is non-empty, only those Gerrit projects are considered.
+func Changelists(corpus *maintner.Gerrit, emails []string, start, until time.Time, projects []string) (map[string][][]string, error) {
+	if corpus == nil {
+		return nil, errors.New("no Gerrit corpus available")
+	}
+	owners := make(map[string]bool)
+	for _, email := range emails {
+		if email = strings.TrimSpace(email); email != "" {
+			owners[email] = true
+		}
+	}
+	projectFilter := make(map[string]bool)
+	for _, p := range projects {
+		if p = strings.TrimSpace(p); p != "" {
+			projectFilter[p] = true
+		}
+	}
+
+	data := make(map[string][][]string)
+	err := corpus.ForeachProjectUnsorted(func(p *maintner.GerritProject) error {
+		if len(projectFilter) > 0 && !projectFilter[p.Project()] {
+			return nil
+		}
+		rows := [][]string{
+			{"Status", "CL", "Subject", "Created"},
+		}
+		var total int
+		if err := p.ForeachCLUnsorted(func(cl *maintner.GerritCL) error {
+			owner := cl.Owner()
+			if owner == nil || !owners[owner.Email()] {
+				return nil
+			}
+			if cl.Created.Before(start) {
+				return nil
+			}
+			if !until.IsZero() && !cl.Created.Before(until) {
+				return nil
+			}
+			rows = append(rows, []string{
+				strings.Title(cl.Status),
+				fmt.Sprintf("https://go-review.googlesource.com/c/%s/+/%d", p.Project(), cl.Number),
+				cl.Subject(),
+				cl.Created.Format("2006-01-02"),
+			})
+			total++
+			return nil
+		}); err != nil {
+			return err
+		}
+		if total == 0 {
+			return nil
+		}
+		sort.Slice(rows[1:], func(i, j int) bool {
+			return rows[1:][i][3] < rows[1:][j][3]
+		})
+		rows = append(rows, []string{"Total", "", "", fmt.Sprintf("%d", total)})
+		data[fmt.Sprintf("%s CLs", p.Project())] = rows
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "iterating Gerrit projects")
+	}
+	return data, nil
+}
+
+// AverageTimeToMerge reports the average time between creation and merge
+// for the CLs sent by any of the given emails in [start, until), along
+// with how many merged CLs that average is based on. A zero until means no
+// upper bound. If projects is non-empty, only those Gerrit projects are
+// considered.
+func AverageTimeToMerge(corpus *maintner.Gerrit, emails []string, start, until time.Time, projects []string) (time.Duration, int, error) {
+	if corpus == nil {
+		return 0, 0, errors.New("no Gerrit corpus available")
+	}
+	owners := make(map[string]bool)
+	for _, email := range emails {
+		if email = strings.TrimSpace(email); email != "" {
+			owners[email] = true
+		}
+	}
+	projectFilter := make(map[string]bool)
+	for _, p := range projects {
+		if p = strings.TrimSpace(p); p != "" {
+			projectFilter[p] = true
+		}
+	}
+
+	var total time.Duration
+	var n int
+	err := corpus.ForeachProjectUnsorted(func(p *maintner.GerritProject) error {
+		if len(projectFilter) > 0 && !projectFilter[p.Project()] {
+			return nil
+		}
+		return p.ForeachCLUnsorted(func(cl *maintner.GerritCL) error {
+			owner := cl.Owner()
+			if owner == nil || !owners[owner.Email()] || cl.Status != "merged" {
+				return nil
+			}
+			if cl.Created.Before(start) {
+				return nil
+			}
+			if !until.IsZero() && !cl.Created.Before(until) {
+				return nil
+			}
+			total += cl.Meta.Commit.CommitTime.Sub(cl.Created)
+			n++
+			return nil
+		})
+	})
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "iterating Gerrit projects")
+	}
+	if n == 0 {
+		return 0, 0, nil
+	}
+	return total / time.Duration(n), n, nil
+}
+
+// reviewLatencyBucket accumulates the review-latency sums for one
+// (week, project) pair, so ReviewLatency can report an average per bucket
+// without holding every individual CL's durations in memory.
+type reviewLatencyBucket struct {
+	cls        int
+	reviewSum  time.Duration
+	reviewN    int
+	plusTwoSum time.Duration
+	plusTwoN   int
+	submitSum  time.Duration
+	submitN    int
+}
+
+// firstPlusTwo returns the time of the earliest Code-Review=+2 vote on cl,
+// or the zero Time if it never received one.
+func firstPlusTwo(cl *maintner.GerritCL) time.Time {
+	for _, m := range cl.Metas {
+		if strings.Contains(m.Footer(), "Label: Code-Review=+2") {
+			return m.Commit.CommitTime
+		}
+	}
+	return time.Time{}
+}
+
+// firstHumanReview returns the time of the earliest message on cl from
+// someone other than its owner, or the zero Time if there isn't one.
+func firstHumanReview(cl *maintner.GerritCL, ownerEmail string) time.Time {
+	for _, msg := range cl.Messages {
+		if msg.Author == nil || msg.Author.Email() == ownerEmail {
+			continue
+		}
+		return msg.Date
+	}
+	return time.Time{}
+}
+
+// ReviewLatency reports, for the Gerrit CLs sent by any of the given
+// emails in [start, until), the average time to first human review, to
+// first Code-Review+2, and to submit, bucketed by week and by Gerrit
+// project. A zero until means no upper bound. If projects is non-empty,
+// only those Gerrit projects are considered.
+func ReviewLatency(corpus *maintner.Gerrit, emails []string, start, until time.Time, projects []string) (map[string][][]string, error) {
+	if corpus == nil {
+		return nil, errors.New("no Gerrit corpus available")
+	}
+	owners := make(map[string]bool)
+	for _, email := range emails {
+		if email = strings.TrimSpace(email); email != "" {
+			owners[email] = true
+		}
+	}
+	projectFilter := make(map[string]bool)
+	for _, p := range projects {
+		if p = strings.TrimSpace(p); p != "" {
+			projectFilter[p] = true
+		}
+	}
+
+	buckets := make(map[[2]string]*reviewLatencyBucket)
+	grand := &reviewLatencyBucket{}
+	err := corpus.ForeachProjectUnsorted(func(p *maintner.GerritProject) error {
+		if len(projectFilter) > 0 && !projectFilter[p.Project()] {
+			return nil
+		}
+		return p.ForeachCLUnsorted(func(cl *maintner.GerritCL) error {
+			owner := cl.Owner()
+			if owner == nil || !owners[owner.Email()] {
+				return nil
+			}
+			if cl.Created.Before(start) {
+				return nil
+			}
+			if !until.IsZero() && !cl.Created.Before(until) {
+				return nil
+			}
+			year, week := cl.Created.ISOWeek()
+			key := [2]string{fmt.Sprintf("%d-W%02d", year, week), p.Project()}
+			b, ok := buckets[key]
+			if !ok {
+				b = &reviewLatencyBucket{}
+				buckets[key] = b
+			}
+			b.cls++
+			grand.cls++
+			if t := firstHumanReview(cl, owner.Email()); !t.IsZero() {
+				d := t.Sub(cl.Created)
+				b.reviewSum += d
+				b.reviewN++
+				grand.reviewSum += d
+				grand.reviewN++
+			}
+			if t := firstPlusTwo(cl); !t.IsZero() {
+				d := t.Sub(cl.Created)
+				b.plusTwoSum += d
+				b.plusTwoN++
+				grand.plusTwoSum += d
+				grand.plusTwoN++
+			}
+			if cl.Status == "merged" {
+				d := cl.Meta.Commit.CommitTime.Sub(cl.Created)
+				b.submitSum += d
+				b.submitN++
+				grand.submitSum += d
+				grand.submitN++
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "iterating Gerrit projects")
+	}
+
+	var keys [][2]string
+	for key := range buckets {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i][0] != keys[j][0] {
+			return keys[i][0] < keys[j][0]
+		}
+		return keys[i][1] < keys[j][1]
+	})
+
+	rows := [][]string{
+		{"Week", "Project", "CLs", "Avg Time to First Review", "Avg Time to First +2", "Avg Time to Submit"},
+	}
+	for _, key := range keys {
+		b := buckets[key]
+		rows = append(rows, []string{
+			key[0],
+			key[1],
+			fmt.Sprintf("%d", b.cls),
+			averageDuration(b.reviewSum, b.reviewN),
+			averageDuration(b.plusTwoSum, b.plusTwoN),
+			averageDuration(b.submitSum, b.submitN),
+		})
+	}
+	rows = append(rows, []string{
+		"Total",
+		"",
+		fmt.Sprintf("%d", grand.cls),
+		averageDuration(grand.reviewSum, grand.reviewN),
+		averageDuration(grand.plusTwoSum, grand.plusTwoN),
+		averageDuration(grand.submitSum, grand.submitN),
+	})
+	return map[string][][]string{"Review Latency": rows}, nil
+}
+
+// averageDuration formats sum/n rounded to the hour, or "n/a" if n is 0.
+func averageDuration(sum time.Duration, n int) string {
+	if n == 0 {
+		return "n/a"
+	}
+	return (sum / time.Duration(n)).Round(time.Hour).String()
+}
+
+// AverageFirstResponseTime reports the average time between creation and
+// first comment from someone else, for the golang/go issues that username
+// filed in [start, until), along with how many issues that average is
+// based on. A zero until means no upper bound.
+func AverageFirstResponseTime(corpus *maintner.GitHub, username string, start, until time.Time) (time.Duration, int, error) {
+	if corpus == nil {
+		return 0, 0, errors.New("no GitHub corpus available")
+	}
+	repo := corpus.Repo(goGitHubOwner, goGitHubRepo)
+	if repo == nil {
+		return 0, 0, errors.Errorf("no data for %s/%s", goGitHubOwner, goGitHubRepo)
+	}
+
+	var total time.Duration
+	var n int
+	err := repo.ForeachIssue(func(issue *maintner.GitHubIssue) error {
+		if issue.PullRequest || issue.NotExist {
+			return nil
+		}
+		if issue.User == nil || issue.User.Login != username {
+			return nil
+		}
+		if issue.Created.Before(start) {
+			return nil
+		}
+		if !until.IsZero() && !issue.Created.Before(until) {
+			return nil
+		}
+		var first time.Time
+		if err := issue.ForeachComment(func(c *maintner.GitHubComment) error {
+			if c.User != nil && c.User.Login == username {
+				return nil
+			}
+			if first.IsZero() || c.Created.Before(first) {
+				first = c.Created
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+		if first.IsZero() {
+			return nil
+		}
+		total += first.Sub(issue.Created)
+		n++
+		return nil
+	})
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "iterating golang/go issues")
+	}
+	if n == 0 {
+		return 0, 0, nil
+	}
+	return total / time.Duration(n), n, nil
+}
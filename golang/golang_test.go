@@ -0,0 +1,75 @@
+package golang
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/build/maintner"
+)
+
+func TestAverageDuration(t *testing.T) {
+	tests := []struct {
+		name string
+		sum  time.Duration
+		n    int
+		want string
+	}{
+		{"no samples", 0, 0, "n/a"},
+		{"one sample", 2 * time.Hour, 1, "2h0m0s"},
+		{"rounds to the hour", 90 * time.Minute, 1, "2h0m0s"},
+		{"averages multiple samples", 6 * time.Hour, 3, "2h0m0s"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := averageDuration(tt.sum, tt.n); got != tt.want {
+				t.Errorf("averageDuration(%v, %d) = %q, want %q", tt.sum, tt.n, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFirstPlusTwo(t *testing.T) {
+	plusTwoTime := time.Date(2026, time.January, 2, 0, 0, 0, 0, time.UTC)
+	withPlusTwo := &maintner.GerritCL{
+		Metas: []*maintner.GerritMeta{
+			{Commit: &maintner.GitCommit{Msg: "Patch Set 1\n\nLabel: Code-Review=+1\n"}},
+			{Commit: &maintner.GitCommit{Msg: "Patch Set 2\n\nLabel: Code-Review=+2\n", CommitTime: plusTwoTime}},
+		},
+	}
+	withoutPlusTwo := &maintner.GerritCL{
+		Metas: []*maintner.GerritMeta{
+			{Commit: &maintner.GitCommit{Msg: "Patch Set 1\n\nLabel: Code-Review=+1\n"}},
+		},
+	}
+
+	if got := firstPlusTwo(withPlusTwo); !got.Equal(plusTwoTime) {
+		t.Errorf("firstPlusTwo(withPlusTwo) = %v, want %v", got, plusTwoTime)
+	}
+	if got := firstPlusTwo(withoutPlusTwo); !got.IsZero() {
+		t.Errorf("firstPlusTwo(withoutPlusTwo) = %v, want zero Time", got)
+	}
+}
+
+func TestFirstHumanReview(t *testing.T) {
+	const ownerEmail = "owner@golang.org"
+	reviewTime := time.Date(2026, time.January, 3, 0, 0, 0, 0, time.UTC)
+	cl := &maintner.GerritCL{
+		Messages: []*maintner.GerritMessage{
+			{Author: &maintner.GitPerson{Str: "Owner <owner@golang.org>"}, Date: reviewTime.Add(-time.Hour)},
+			{Author: &maintner.GitPerson{Str: "Reviewer <reviewer@golang.org>"}, Date: reviewTime},
+			{Author: &maintner.GitPerson{Str: "Reviewer <reviewer@golang.org>"}, Date: reviewTime.Add(time.Hour)},
+		},
+	}
+	if got := firstHumanReview(cl, ownerEmail); !got.Equal(reviewTime) {
+		t.Errorf("firstHumanReview(cl, %q) = %v, want %v", ownerEmail, got, reviewTime)
+	}
+
+	onlyOwner := &maintner.GerritCL{
+		Messages: []*maintner.GerritMessage{
+			{Author: &maintner.GitPerson{Str: "Owner <owner@golang.org>"}, Date: reviewTime},
+		},
+	}
+	if got := firstHumanReview(onlyOwner, ownerEmail); !got.IsZero() {
+		t.Errorf("firstHumanReview(onlyOwner, %q) = %v, want zero Time", ownerEmail, got)
+	}
+}
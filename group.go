@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stamblerre/work-stats/github"
+	"github.com/stamblerre/work-stats/golang"
+	"golang.org/x/build/maintner"
+	"gopkg.in/yaml.v2"
+)
+
+// contributor is a single member of a team being reported on together via
+// the -group flag.
+type contributor struct {
+	Username string   `json:"username" yaml:"username"`
+	Emails   []string `json:"emails" yaml:"emails"`
+}
+
+// parseGroup parses the value of the -group flag, which is either the path
+// to a YAML or JSON file describing a team, or an inline
+// "user1:email1,email2;user2:email3" list of entries.
+func parseGroup(spec string) ([]contributor, error) {
+	if _, err := os.Stat(spec); err == nil {
+		b, err := ioutil.ReadFile(spec)
+		if err != nil {
+			return nil, err
+		}
+		var team []contributor
+		if strings.HasSuffix(spec, ".json") {
+			err = json.Unmarshal(b, &team)
+		} else {
+			err = yaml.Unmarshal(b, &team)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parsing team file %s: %v", spec, err)
+		}
+		return team, nil
+	}
+	var team []contributor
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid -group entry %q: want user:email1,email2", entry)
+		}
+		team = append(team, contributor{
+			Username: strings.TrimSpace(parts[0]),
+			Emails:   strings.Split(parts[1], ","),
+		})
+	}
+	if len(team) == 0 {
+		return nil, fmt.Errorf("no contributors found in -group %q", spec)
+	}
+	return team, nil
+}
+
+// collect gathers all of a single contributor's data in [start, until),
+// exactly as the single-contributor code path always has. A zero until
+// means no upper bound. If projects is non-empty, Gerrit CLs are
+// restricted to those projects.
+func collect(ctx context.Context, corpus *maintner.Corpus, c contributor, start, until time.Time, projects []string) (map[string][][]string, error) {
+	data := make(map[string][][]string)
+	if *gerritFlag {
+		goIssues, err := golang.Issues(corpus.GitHub(), c.Username, start, until)
+		if err != nil {
+			return nil, err
+		}
+		for title, rows := range goIssues {
+			data[title] = rows
+		}
+		goCLs, err := golang.Changelists(corpus.Gerrit(), c.Emails, start, until, projects)
+		if err != nil {
+			return nil, err
+		}
+		for title, rows := range goCLs {
+			data[title] = rows
+		}
+	}
+	if *gitHubFlag {
+		githubIssues, err := github.IssuesAndPRs(ctx, c.Username, start, until)
+		if err != nil {
+			return nil, err
+		}
+		for title, rows := range githubIssues {
+			data[title] = rows
+		}
+	}
+	return data, nil
+}
+
+// rollup accumulates, for each contributor and each category of data (e.g.
+// "Go Issues"), a per-contributor subtotal and a grand total per category,
+// mirroring the subtotal/total row styling that write already applies.
+type rollup struct {
+	// order preserves the order in which categories were first seen, so the
+	// roll-up sheet reads in a stable order across runs.
+	order      []string
+	byCategory map[string]map[string]int // category -> username -> count
+}
+
+func newRollup() *rollup {
+	return &rollup{byCategory: make(map[string]map[string]int)}
+}
+
+// add records the subtotal for one contributor's sheet. The count is read
+// off of the "Total" row that golang.Issues, golang.Changelists, and
+// github.IssuesAndPRs already append to their output.
+func (r *rollup) add(username, category string, rows [][]string) {
+	if _, ok := r.byCategory[category]; !ok {
+		r.byCategory[category] = make(map[string]int)
+		r.order = append(r.order, category)
+	}
+	r.byCategory[category][username] += countRows(rows)
+}
+
+// countRows returns the count recorded in a data sheet's trailing "Total"
+// row, or the number of data rows if no such row is present.
+func countRows(rows [][]string) int {
+	if len(rows) == 0 {
+		return 0
+	}
+	last := rows[len(rows)-1]
+	if len(last) > 0 && last[0] == "Total" {
+		if n, err := strconv.Atoi(last[len(last)-1]); err == nil {
+			return n
+		}
+	}
+	if len(rows) == 0 {
+		return 0
+	}
+	return len(rows) - 1
+}
+
+// sheet renders the accumulated per-contributor subtotals and per-category
+// grand totals into a single "Team Rollup" sheet.
+func (r *rollup) sheet() map[string][][]string {
+	rows := [][]string{
+		{"Contributor", "Category", "Count"},
+	}
+	for _, category := range r.order {
+		counts := r.byCategory[category]
+		var usernames []string
+		for username := range counts {
+			usernames = append(usernames, username)
+		}
+		sort.Strings(usernames)
+
+		var grandTotal int
+		for _, username := range usernames {
+			rows = append(rows, []string{"Subtotal", username + ": " + category, strconv.Itoa(counts[username])})
+			grandTotal += counts[username]
+		}
+		rows = append(rows, []string{"Total", category, strconv.Itoa(grandTotal)})
+	}
+	return map[string][][]string{"Team Rollup": rows}
+}
@@ -0,0 +1,301 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+// appendToSheet incrementally updates an existing spreadsheet: tabs that
+// don't exist yet are added from scratch, and tabs that do exist are
+// diffed by key column against the freshly computed rowData, so that
+// re-running work-stats against the same spreadsheet (e.g. weekly) grows a
+// ledger instead of producing duplicate tabs or duplicate rows.
+func appendToSheet(ctx context.Context, srv *sheets.Service, spreadsheetID string, rowData map[string][]*sheets.RowData) (*sheets.Spreadsheet, error) {
+	spreadsheet, err := srv.Spreadsheets.Get(spreadsheetID).Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+	sheetsByTitle := make(map[string]*sheets.Sheet)
+	for _, sheet := range spreadsheet.Sheets {
+		sheetsByTitle[sheet.Properties.Title] = sheet
+	}
+
+	// Add any tabs that don't exist yet.
+	var createRequests []*sheets.Request
+	for title := range rowData {
+		if _, ok := sheetsByTitle[title]; ok {
+			continue
+		}
+		createRequests = append(createRequests, &sheets.Request{
+			AddSheet: &sheets.AddSheetRequest{
+				Properties: &sheets.SheetProperties{
+					Title: title,
+					GridProperties: &sheets.GridProperties{
+						FrozenRowCount: 1,
+					},
+				},
+			},
+		})
+	}
+	if len(createRequests) > 0 {
+		resp, err := srv.Spreadsheets.BatchUpdate(spreadsheetID, &sheets.BatchUpdateSpreadsheetRequest{
+			IncludeSpreadsheetInResponse: true,
+			Requests:                     createRequests,
+		}).Context(ctx).Do()
+		if err != nil {
+			return nil, err
+		}
+		spreadsheet = resp.UpdatedSpreadsheet
+		for _, sheet := range spreadsheet.Sheets {
+			sheetsByTitle[sheet.Properties.Title] = sheet
+		}
+	}
+
+	// Diff each tab's existing contents against the new rows: rows whose
+	// key isn't present yet are appended, rows whose key already exists
+	// but whose content has changed (e.g. a CL's status going from "New"
+	// to "Merged") are updated in place, and the trailing "Total" row -
+	// whose key column is blank in the CL, issue and link sheets, so it
+	// can't be deduped like a data row there - is split off and always
+	// updated (or appended) on its own rather than being matched against
+	// the key index.
+	var dataRequests []*sheets.Request
+	for title, rows := range rowData {
+		sheet := sheetsByTitle[title]
+		existing, err := readSheetValuesRange(ctx, srv, spreadsheetID, title)
+		if err != nil {
+			return nil, err
+		}
+		if len(existing) == 0 {
+			dataRequests = append(dataRequests, appendCellsRequest(sheet, rows))
+			continue
+		}
+		if len(rows) == 0 {
+			continue
+		}
+		header := rowStrings(rows[0])
+		if !equalStrings(existing[0], header) {
+			log.Printf("work-stats: skipping tab %q: its header %v doesn't match the current header %v", title, existing[0], header)
+			continue
+		}
+
+		existingData, existingTotalIdx := splitTrailingTotalStrings(existing)
+		freshData, freshTotal := splitTrailingTotalRows(rows)
+
+		keyOf := rowKeyFunc(header)
+		existingByKey := make(map[string]int) // key -> row index within existing
+		if keyOf != nil {
+			for i, row := range existingData {
+				if key, ok := keyOf(row); ok {
+					existingByKey[key] = i + 1 // +1 to account for the header row
+				}
+			}
+		}
+
+		var newRows []*sheets.RowData
+		for _, row := range freshData {
+			cells := rowStrings(row)
+			if keyOf == nil {
+				newRows = append(newRows, row)
+				continue
+			}
+			key, ok := keyOf(cells)
+			if !ok {
+				newRows = append(newRows, row)
+				continue
+			}
+			idx, ok := existingByKey[key]
+			if !ok {
+				newRows = append(newRows, row)
+				continue
+			}
+			if !equalStrings(existing[idx], cells) {
+				dataRequests = append(dataRequests, updateCellsRequest(sheet, idx, row))
+			}
+		}
+		if len(newRows) > 0 {
+			dataRequests = append(dataRequests, appendCellsRequest(sheet, newRows))
+		}
+
+		if freshTotal == nil {
+			continue
+		}
+		if existingTotalIdx < 0 {
+			dataRequests = append(dataRequests, appendCellsRequest(sheet, []*sheets.RowData{freshTotal}))
+			continue
+		}
+		if !equalStrings(existing[existingTotalIdx], rowStrings(freshTotal)) {
+			dataRequests = append(dataRequests, updateCellsRequest(sheet, existingTotalIdx, freshTotal))
+		}
+	}
+	if len(dataRequests) == 0 {
+		return spreadsheet, nil
+	}
+	resp, err := srv.Spreadsheets.BatchUpdate(spreadsheetID, &sheets.BatchUpdateSpreadsheetRequest{
+		IncludeSpreadsheetInResponse: true,
+		Requests:                     dataRequests,
+	}).Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+	return resp.UpdatedSpreadsheet, nil
+}
+
+func appendCellsRequest(sheet *sheets.Sheet, rows []*sheets.RowData) *sheets.Request {
+	return &sheets.Request{
+		AppendCells: &sheets.AppendCellsRequest{
+			SheetId: sheet.Properties.SheetId,
+			Rows:    rows,
+			Fields:  "*",
+		},
+	}
+}
+
+// updateCellsRequest overwrites the row at rowIndex (0-based, including
+// the header) with row, for rows whose key column matched but whose
+// content has since changed.
+func updateCellsRequest(sheet *sheets.Sheet, rowIndex int, row *sheets.RowData) *sheets.Request {
+	return &sheets.Request{
+		UpdateCells: &sheets.UpdateCellsRequest{
+			Start: &sheets.GridCoordinate{
+				SheetId:     sheet.Properties.SheetId,
+				RowIndex:    int64(rowIndex),
+				ColumnIndex: 0,
+			},
+			Rows:   []*sheets.RowData{row},
+			Fields: "*",
+		},
+	}
+}
+
+// readSheetValuesRange reads back the current contents of a tab, so that
+// appendToSheet can diff against it instead of blindly re-writing rows.
+func readSheetValuesRange(ctx context.Context, srv *sheets.Service, spreadsheetID, title string) ([][]string, error) {
+	resp, err := srv.Spreadsheets.Values.Get(spreadsheetID, title).Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+	rows := make([][]string, len(resp.Values))
+	for i, row := range resp.Values {
+		cells := make([]string, len(row))
+		for j, cell := range row {
+			cells[j], _ = cell.(string)
+		}
+		rows[i] = cells
+	}
+	return rows, nil
+}
+
+// rowStrings flattens a formatted RowData back down to its plain string
+// values, for diffing against values read back from the sheet.
+func rowStrings(row *sheets.RowData) []string {
+	cells := make([]string, len(row.Values))
+	for i, cd := range row.Values {
+		if cd.UserEnteredValue != nil {
+			cells[i] = cd.UserEnteredValue.StringValue
+		}
+	}
+	return cells
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// splitTrailingTotalStrings separates existing's trailing "Total" row (if
+// any) from its data rows, returning the data rows and the Total row's
+// index in existing, or -1 if existing has none.
+func splitTrailingTotalStrings(existing [][]string) ([][]string, int) {
+	n := len(existing)
+	if n > 1 && len(existing[n-1]) > 0 && existing[n-1][0] == "Total" {
+		return existing[1 : n-1], n - 1
+	}
+	return existing[1:], -1
+}
+
+// splitTrailingTotalRows separates rows' trailing "Total" row (if any)
+// from its data rows, returning the data rows and the Total row, or nil
+// if rows has none.
+func splitTrailingTotalRows(rows []*sheets.RowData) ([]*sheets.RowData, *sheets.RowData) {
+	n := len(rows)
+	if n > 1 {
+		if cells := rowStrings(rows[n-1]); len(cells) > 0 && cells[0] == "Total" {
+			return rows[1 : n-1], rows[n-1]
+		}
+	}
+	return rows[1:], nil
+}
+
+// columnIndex returns the index of the first header column matching one of
+// names, or -1 if header has none of them.
+func columnIndex(header []string, names ...string) int {
+	for i, h := range header {
+		for _, name := range names {
+			if h == name {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// rowKeyFunc returns a function that computes the dedup key for a data row
+// of a sheet with the given header, so appendToSheet can tell which rows
+// are already present and which have changed. It returns nil if the sheet
+// has no columns that combine into a stable key, in which case every row
+// is always (re-)appended.
+//
+// Sheets with a "CL", "Issue", or "Link" column (the CL sheets, the Go
+// issues sheet, the GitHub issue/PR report) key on that column alone. The
+// per-week analyses (ReviewLatency, FirstResponseTime) have no such
+// column, but "Week" (plus "Project" for ReviewLatency, which buckets by
+// week and project) uniquely identifies a row. The Team Rollup has
+// neither, but its "Contributor" column (always "Subtotal" or "Total")
+// paired with "Category" (which embeds the username for subtotal rows,
+// see rollup.sheet) is likewise unique per row. The issue-close-stats
+// sheet ("Status", "Count") matches none of these, so it gets no key.
+func rowKeyFunc(header []string) func(row []string) (string, bool) {
+	if i := columnIndex(header, "CL", "Issue", "Link"); i >= 0 {
+		return func(row []string) (string, bool) {
+			if i >= len(row) {
+				return "", false
+			}
+			return row[i], true
+		}
+	}
+	if week, project := columnIndex(header, "Week"), columnIndex(header, "Project"); week >= 0 && project >= 0 {
+		return func(row []string) (string, bool) {
+			if week >= len(row) || project >= len(row) {
+				return "", false
+			}
+			return row[week] + "|" + row[project], true
+		}
+	}
+	if week := columnIndex(header, "Week"); week >= 0 {
+		return func(row []string) (string, bool) {
+			if week >= len(row) {
+				return "", false
+			}
+			return row[week], true
+		}
+	}
+	if contributor, category := columnIndex(header, "Contributor"), columnIndex(header, "Category"); contributor >= 0 && category >= 0 {
+		return func(row []string) (string, bool) {
+			if contributor >= len(row) || category >= len(row) {
+				return "", false
+			}
+			return row[contributor] + "|" + row[category], true
+		}
+	}
+	return nil
+}
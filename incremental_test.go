@@ -0,0 +1,226 @@
+package main
+
+import (
+	"testing"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+func TestColumnIndex(t *testing.T) {
+	tests := []struct {
+		name   string
+		header []string
+		names  []string
+		want   int
+	}{
+		{"first match", []string{"CL", "Status"}, []string{"CL", "Issue", "Link"}, 0},
+		{"later column", []string{"Status", "Issue"}, []string{"CL", "Issue", "Link"}, 1},
+		{"no match", []string{"Status", "Count"}, []string{"CL", "Issue", "Link"}, -1},
+		{"empty header", nil, []string{"CL"}, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := columnIndex(tt.header, tt.names...); got != tt.want {
+				t.Errorf("columnIndex(%v, %v) = %d, want %d", tt.header, tt.names, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRowKeyFunc(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  []string
+		row     []string
+		wantKey string
+		wantOk  bool
+		noKey   bool // rowKeyFunc itself should return nil
+	}{
+		{
+			name:    "CL column",
+			header:  []string{"CL", "Status"},
+			row:     []string{"https://go-review.googlesource.com/c/go/+/1", "Merged"},
+			wantKey: "https://go-review.googlesource.com/c/go/+/1",
+			wantOk:  true,
+		},
+		{
+			name:    "week and project",
+			header:  []string{"Week", "Project", "CLs"},
+			row:     []string{"2026-W05", "go", "3"},
+			wantKey: "2026-W05|go",
+			wantOk:  true,
+		},
+		{
+			name:    "week alone",
+			header:  []string{"Week", "Issues Considered"},
+			row:     []string{"2026-W05", "4"},
+			wantKey: "2026-W05",
+			wantOk:  true,
+		},
+		{
+			name:    "contributor and category",
+			header:  []string{"Contributor", "Category", "Count"},
+			row:     []string{"Subtotal", "alice: Go Issues", "3"},
+			wantKey: "Subtotal|alice: Go Issues",
+			wantOk:  true,
+		},
+		{
+			name:   "no recognized columns",
+			header: []string{"Status", "Count"},
+			noKey:  true,
+		},
+		{
+			name:   "short row misses the key column",
+			header: []string{"Status", "CL"},
+			row:    []string{"Open"},
+			wantOk: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keyOf := rowKeyFunc(tt.header)
+			if tt.noKey {
+				if keyOf != nil {
+					t.Fatalf("rowKeyFunc(%v) = non-nil, want nil", tt.header)
+				}
+				return
+			}
+			if keyOf == nil {
+				t.Fatalf("rowKeyFunc(%v) = nil, want a key function", tt.header)
+			}
+			key, ok := keyOf(tt.row)
+			if ok != tt.wantOk {
+				t.Fatalf("keyOf(%v) ok = %v, want %v", tt.row, ok, tt.wantOk)
+			}
+			if ok && key != tt.wantKey {
+				t.Errorf("keyOf(%v) = %q, want %q", tt.row, key, tt.wantKey)
+			}
+		})
+	}
+}
+
+func TestSplitTrailingTotalStrings(t *testing.T) {
+	tests := []struct {
+		name         string
+		existing     [][]string
+		wantData     [][]string
+		wantTotalIdx int
+	}{
+		{
+			name:         "with trailing total",
+			existing:     [][]string{{"Week", "CLs"}, {"2026-W05", "1"}, {"Total", "1"}},
+			wantData:     [][]string{{"2026-W05", "1"}},
+			wantTotalIdx: 2,
+		},
+		{
+			name:         "without trailing total",
+			existing:     [][]string{{"Week", "CLs"}, {"2026-W05", "1"}},
+			wantData:     [][]string{{"2026-W05", "1"}},
+			wantTotalIdx: -1,
+		},
+		{
+			name:         "header only",
+			existing:     [][]string{{"Week", "CLs"}},
+			wantData:     [][]string{},
+			wantTotalIdx: -1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, totalIdx := splitTrailingTotalStrings(tt.existing)
+			if !equalRowsOfStrings(data, tt.wantData) {
+				t.Errorf("data = %v, want %v", data, tt.wantData)
+			}
+			if totalIdx != tt.wantTotalIdx {
+				t.Errorf("totalIdx = %d, want %d", totalIdx, tt.wantTotalIdx)
+			}
+		})
+	}
+}
+
+func TestSplitTrailingTotalRows(t *testing.T) {
+	header := stringRow("Week", "CLs")
+	data := stringRow("2026-W05", "1")
+	total := stringRow("Total", "1")
+
+	tests := []struct {
+		name          string
+		rows          []*sheets.RowData
+		wantDataCount int
+		wantTotal     *sheets.RowData
+	}{
+		{
+			name:          "with trailing total",
+			rows:          []*sheets.RowData{header, data, total},
+			wantDataCount: 1,
+			wantTotal:     total,
+		},
+		{
+			name:          "without trailing total",
+			rows:          []*sheets.RowData{header, data},
+			wantDataCount: 1,
+			wantTotal:     nil,
+		},
+		{
+			name:          "header only",
+			rows:          []*sheets.RowData{header},
+			wantDataCount: 0,
+			wantTotal:     nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotData, gotTotal := splitTrailingTotalRows(tt.rows)
+			if len(gotData) != tt.wantDataCount {
+				t.Errorf("len(data) = %d, want %d", len(gotData), tt.wantDataCount)
+			}
+			if (gotTotal == nil) != (tt.wantTotal == nil) {
+				t.Errorf("total = %v, want %v", gotTotal, tt.wantTotal)
+			}
+		})
+	}
+}
+
+func TestEqualStrings(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []string
+		want bool
+	}{
+		{"equal", []string{"a", "b"}, []string{"a", "b"}, true},
+		{"different values", []string{"a", "b"}, []string{"a", "c"}, false},
+		{"different lengths", []string{"a", "b"}, []string{"a"}, false},
+		{"both empty", nil, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := equalStrings(tt.a, tt.b); got != tt.want {
+				t.Errorf("equalStrings(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+// stringRow builds a *sheets.RowData with the given plain string values,
+// the way rowStrings expects to read them back.
+func stringRow(cells ...string) *sheets.RowData {
+	values := make([]*sheets.CellData, len(cells))
+	for i, cell := range cells {
+		values[i] = &sheets.CellData{
+			UserEnteredValue: &sheets.ExtendedValue{StringValue: cell},
+		}
+	}
+	return &sheets.RowData{Values: values}
+}
+
+func equalRowsOfStrings(a, b [][]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !equalStrings(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
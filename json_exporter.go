@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// jsonRow is a single row of newline-delimited JSON output: the sheet it
+// belongs to, its cells in order, and its RowStyle, so downstream tooling
+// can distinguish totals and subtotals without re-deriving them from the
+// first cell's text.
+type jsonRow struct {
+	Sheet string   `json:"sheet"`
+	Cells []string `json:"cells"`
+	Style string   `json:"style"`
+}
+
+var rowStyleNames = map[RowStyle]string{
+	RowNormal:   "normal",
+	RowHeader:   "header",
+	RowSubtotal: "subtotal",
+	RowTotal:    "total",
+}
+
+// jsonExporter writes every sheet's rows as newline-delimited JSON to a
+// single "<dir>/data.ndjson" file, so the output can be piped into
+// downstream tooling regardless of how many sheets were produced.
+type jsonExporter struct {
+	dir     string
+	file    *os.File
+	enc     *json.Encoder
+	path    string
+	current string
+}
+
+func newJSONExporter(dir string) *jsonExporter {
+	return &jsonExporter{dir: dir}
+}
+
+func (e *jsonExporter) Begin(title string) error {
+	e.current = title
+	if e.file != nil {
+		return nil
+	}
+	e.path = filepath.Join(e.dir, "data.ndjson")
+	file, err := os.Create(e.path)
+	if err != nil {
+		return err
+	}
+	e.file = file
+	e.enc = json.NewEncoder(file)
+	return nil
+}
+
+func (e *jsonExporter) Row(cells []string, style RowStyle) error {
+	return e.enc.Encode(jsonRow{
+		Sheet: e.current,
+		Cells: cells,
+		Style: rowStyleNames[style],
+	})
+}
+
+func (e *jsonExporter) End() error {
+	return nil
+}
+
+func (e *jsonExporter) Flush() error {
+	if e.file == nil {
+		return nil
+	}
+	if err := e.file.Close(); err != nil {
+		return err
+	}
+	fmt.Printf("Wrote output to %s.\n", e.path)
+	return nil
+}
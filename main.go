@@ -2,53 +2,79 @@ package main
 
 import (
 	"context"
-	"encoding/csv"
-	"encoding/json"
 	"flag"
 	"fmt"
 	"io/ioutil"
 	"log"
-	"os"
-	"path/filepath"
 	"regexp"
 	"strings"
 	"time"
 
-	"github.com/stamblerre/work-stats/github"
-	"github.com/stamblerre/work-stats/golang"
+	"golang.org/x/build/maintner"
 	"golang.org/x/build/maintner/godata"
-	"golang.org/x/oauth2"
-	"golang.org/x/oauth2/google"
 	"google.golang.org/api/sheets/v4"
 )
 
 var (
-	username = flag.String("username", "", "GitHub username")
-	email    = flag.String("email", "", "Gerrit email or emails, comma-separated")
-	since    = flag.String("since", "", "date from which to collect data")
+	username  = flag.String("username", "", "GitHub username")
+	email     = flag.String("email", "", "Gerrit email or emails, comma-separated")
+	since     = flag.String("since", "", "date from which to collect data")
+	untilFlag = flag.String("until", "", "date until which to collect data (exclusive); defaults to no upper bound")
+	to        = flag.String("to", "", "alias for -until")
 
 	// Optional flags.
 	gerritFlag = flag.Bool("gerrit", true, "collect data on Go issues or changelists")
 	gitHubFlag = flag.Bool("github", true, "collect data on GitHub issues")
 
+	// groupFlag enables team/cohort mode: instead of a single -username and
+	// -email, report on a whole group of contributors at once.
+	groupFlag = flag.String("group", "", "report on a team instead of a single contributor: either \"user1:email1,email2;user2:email3\" or the path to a YAML/JSON file describing the team")
+
+	// modeFlag selects one or more registered analyses to run, instead of
+	// the default Go issues/CLs + GitHub issues report. See modeNames for
+	// the full list.
+	modeFlag = flag.String("mode", "", "comma-separated list of analyses to run, selected from the registered modes (run with no value to use the default report); see modes.go for the full list")
+	// projectsFlag restricts Gerrit analyses (in both the default report and
+	// every mode) to a set of Gerrit projects, e.g. "go,tools,vscode-go".
+	projectsFlag = flag.String("projects", "", "comma-separated list of Gerrit projects to restrict Gerrit analyses to; defaults to all projects")
+
 	// Flags relating to Google sheets exporter.
 	googleSheetsFlag = flag.String("sheets", "new", "write or append output to a Google spreadsheet (either \"\", \"new\", or the URL of an existing sheet)")
 	credentialsFile  = flag.String("credentials", "credentials.json", "path to credentials file for Google Sheets")
 	tokenFile        = flag.String("token", "token.json", "path to token file for authentication in Google sheets")
+
+	// outFlags selects one or more output backends, e.g.
+	// "-out csv:./out -out xlsx:./stats.xlsx". See exporter.go for the set
+	// of registered backends.
+	outFlags outFlag
 )
 
+func init() {
+	flag.Var(&outFlags, "out", "repeatable backend:destination output spec (backends: csv, xlsx, md, json, sheets); defaults to \"csv:<temp dir>\" plus -sheets if set")
+}
+
 func main() {
 	flag.Parse()
 
-	// Username and email are required flags.
+	// Username and email are required flags, unless -group is used to
+	// report on a whole team instead of a single contributor.
 	// If since is omitted, results reflect all history.
-	if *username == "" && *gitHubFlag {
-		log.Fatal("Please provide a GitHub username.")
-	}
-	if *email == "" && *gerritFlag {
-		log.Fatal("Please provide your Gerrit email.")
+	var contributors []contributor
+	if *groupFlag != "" {
+		var err error
+		contributors, err = parseGroup(*groupFlag)
+		if err != nil {
+			log.Fatal(err)
+		}
+	} else {
+		if *username == "" && *gitHubFlag {
+			log.Fatal("Please provide a GitHub username.")
+		}
+		if *email == "" && *gerritFlag {
+			log.Fatal("Please provide your Gerrit email.")
+		}
+		contributors = []contributor{{Username: *username, Emails: strings.Split(*email, ",")}}
 	}
-	emails := strings.Split(*email, ",")
 
 	// Parse out the start date, if provided.
 	var (
@@ -64,11 +90,48 @@ func main() {
 		start = time.Date(1900, time.January, 1, 0, 0, 0, 0, time.UTC)
 	}
 
+	// Parse out the end date, if provided. The zero Time means no upper
+	// bound.
+	var until time.Time
+	if untilStr := firstNonEmpty(*untilFlag, *to); untilStr != "" {
+		until, err = time.Parse("2006-01-02", untilStr)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	var projects []string
+	if *projectsFlag != "" {
+		projects = strings.Split(*projectsFlag, ",")
+	}
+
+	// Resolve the effective Google Sheets target. A "sheets:" -out entry
+	// takes precedence, since it's the explicit way of asking for Sheets
+	// output alongside other backends; otherwise -sheets applies, except
+	// that using -out without a "sheets:" entry turns Sheets off by
+	// default, so that e.g. "-out xlsx:./stats.xlsx" alone doesn't also
+	// try to write to Sheets and fail for lack of credentials.
+	sheetsTarget := *googleSheetsFlag
+	if len(outFlags) > 0 {
+		dest, ok, err := sheetsDestFromOutFlags()
+		if err != nil {
+			log.Fatal(err)
+		}
+		switch {
+		case ok && dest != "":
+			sheetsTarget = dest
+		case ok:
+			sheetsTarget = "new"
+		case !sheetsFlagExplicit():
+			sheetsTarget = ""
+		}
+	}
+
 	// Determine if the user has provided a valid Google Sheets URL.
 	var spreadsheetID string
-	if *googleSheetsFlag != "new" && *googleSheetsFlag != "" {
+	if sheetsTarget != "new" && sheetsTarget != "" {
 		// Trim the extra pieces that the URL may contain.
-		trimmed := strings.TrimPrefix(*googleSheetsFlag, "https://docs.google.com")
+		trimmed := strings.TrimPrefix(sheetsTarget, "https://docs.google.com")
 		trimmed = strings.TrimSuffix(trimmed, "edit#gid=0")
 
 		// Source: https://developers.google.com/sheets/api/guides/concepts.
@@ -83,7 +146,7 @@ func main() {
 			}
 		}
 		if spreadsheetID == "" {
-			log.Fatalf("Unable to determine spreadsheet ID for %s", *googleSheetsFlag)
+			log.Fatalf("Unable to determine spreadsheet ID for %s", sheetsTarget)
 		}
 	}
 
@@ -95,44 +158,83 @@ func main() {
 
 	ctx := context.Background()
 	rowData := make(map[string][]*sheets.RowData)
+	exps, err := buildExporters(dir, rowData)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	// Write out data on the user's activity on the Go project's GitHub issues
-	// and the Go project's Gerrit code reviews.
+	// Get the corpus data (very slow on first try, uses cache after).
+	var corpus *maintner.Corpus
 	if *gerritFlag {
-		// Get the corpus data (very slow on first try, uses cache after).
-		corpus, err := godata.Get(ctx)
+		var err error
+		corpus, err = godata.Get(ctx)
 		if err != nil {
 			log.Fatal(err)
 		}
-		goIssues, err := golang.Issues(corpus.GitHub(), *username, start)
-		if err != nil {
-			log.Fatal(err)
+	}
+
+	if *modeFlag != "" {
+		// Run the selected analyses instead of the default report.
+		p := &modeParams{
+			Corpus:       corpus,
+			Contributors: contributors,
+			Start:        start,
+			Until:        until,
+			Projects:     projects,
 		}
-		if err := write(ctx, dir, goIssues, rowData); err != nil {
-			log.Fatal(err)
+		for _, name := range strings.Split(*modeFlag, ",") {
+			name = strings.TrimSpace(name)
+			m, ok := modes[name]
+			if !ok {
+				log.Fatalf("unknown -mode %q; registered modes are: %s", name, strings.Join(modeNames(), ", "))
+			}
+			data, err := m.Run(ctx, p)
+			if err != nil {
+				log.Fatal(err)
+			}
+			if err := write(data, exps); err != nil {
+				log.Fatal(err)
+			}
 		}
-		goCLs, err := golang.Changelists(corpus.Gerrit(), emails, start)
-		if err != nil {
-			log.Fatal(err)
+	} else {
+		// Write out data on each contributor's activity on the Go project's
+		// GitHub issues and Gerrit code reviews, and on GitHub issues
+		// outside of the Go project. With a single contributor (including
+		// a -group of one), the sheets are left unprefixed, matching the
+		// tool's historical output; with more than one, each
+		// contributor's sheets are prefixed with their username and
+		// rolled up into a team summary sheet.
+		rollup := newRollup()
+		for _, c := range contributors {
+			data, err := collect(ctx, corpus, c, start, until, projects)
+			if err != nil {
+				log.Fatal(err)
+			}
+			prefixed := data
+			if len(contributors) > 1 {
+				prefixed = make(map[string][][]string, len(data))
+				for title, rows := range data {
+					prefixed[fmt.Sprintf("%s: %s", c.Username, title)] = rows
+					rollup.add(c.Username, title, rows)
+				}
+			}
+			if err := write(prefixed, exps); err != nil {
+				log.Fatal(err)
+			}
 		}
-		if err := write(ctx, dir, goCLs, rowData); err != nil {
-			log.Fatal(err)
+		if len(contributors) > 1 {
+			if err := write(rollup.sheet(), exps); err != nil {
+				log.Fatal(err)
+			}
 		}
 	}
 
-	// Write out data on the user's activity on GitHub issues outside of the Go project.
-	if *gitHubFlag {
-		githubIssues, err := github.IssuesAndPRs(ctx, *username, start)
-		if err != nil {
-			log.Fatal(err)
-		}
-		if err := write(ctx, dir, githubIssues, rowData); err != nil {
-			log.Fatal(err)
-		}
+	if err := flushAll(exps); err != nil {
+		log.Fatal(err)
 	}
 
 	// Optionally write output to Google Sheets.
-	if *googleSheetsFlag == "" {
+	if sheetsTarget == "" {
 		return
 	}
 	srv, err := googleSheetsService(ctx)
@@ -140,7 +242,7 @@ func main() {
 		log.Fatal(err)
 	}
 	var spreadsheet *sheets.Spreadsheet
-	if *googleSheetsFlag == "new" {
+	if sheetsTarget == "new" {
 		spreadsheet, err = createSheet(ctx, srv, start, rowData)
 		if err != nil {
 			log.Fatal(err)
@@ -172,134 +274,29 @@ func main() {
 	fmt.Printf("Wrote data to Google Sheet: %s\n", spreadsheet.SpreadsheetUrl)
 }
 
-func write(ctx context.Context, outputDir string, data map[string][][]string, rowData map[string][]*sheets.RowData) error {
-	// Write output to disk first.
-	var filenames []string
-	for filename, cells := range data {
-		fullpath := filepath.Join(outputDir, fmt.Sprintf("%s.csv", filename))
-		file, err := os.Create(fullpath)
-		if err != nil {
-			return err
-		}
-		defer file.Close()
-
-		writer := csv.NewWriter(file)
-		defer writer.Flush()
-
-		for _, row := range cells {
-			if err := writer.Write(row); err != nil {
-				return err
-			}
-		}
-		filenames = append(filenames, fullpath)
-	}
-	for _, filename := range filenames {
-		fmt.Printf("Wrote output to %s.\n", filename)
-	}
-	// Add a new sheet and write output to it.
-	for title, cells := range data {
-		var rd []*sheets.RowData
-		for i, row := range cells {
-			var values []*sheets.CellData
-			for _, cell := range row {
-				var total, subtotal bool
-				if len(row) >= 1 {
-					total = row[0] == "Total"
-					subtotal = row[0] == "Subtotal"
-				}
-				cd := &sheets.CellData{
-					UserEnteredValue: &sheets.ExtendedValue{
-						StringValue: cell,
-					},
-					UserEnteredFormat: &sheets.CellFormat{
-						TextFormat: &sheets.TextFormat{
-							Bold: i == 0 || total || subtotal,
-						},
-					},
-				}
-				if subtotal {
-					cd.UserEnteredFormat.BackgroundColor = &sheets.Color{
-						Blue:  0.96,
-						Green: 0.96,
-						Red:   0.96,
-					}
-				} else if total {
-					cd.UserEnteredFormat.BackgroundColor = &sheets.Color{
-						Blue:  0.92,
-						Green: 0.92,
-						Red:   0.92,
-					}
-				}
-				values = append(values, cd)
-			}
-			rd = append(rd, &sheets.RowData{
-				Values: values,
-			})
+// sheetsFlagExplicit reports whether -sheets was passed on the command
+// line, as opposed to taking its default value, so that using -out without
+// a "sheets:" entry can turn Sheets output off by default without also
+// overriding a user who asked for it explicitly.
+func sheetsFlagExplicit() bool {
+	explicit := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "sheets" {
+			explicit = true
 		}
-		rowData[title] = rd
-	}
-	return nil
+	})
+	return explicit
 }
 
-func googleSheetsService(ctx context.Context) (*sheets.Service, error) {
-	// Read the user's credentials file.
-	b, err := ioutil.ReadFile(*credentialsFile)
-	if err != nil {
-		return nil, err
-	}
-	// If modifying these scopes, delete your previously saved token.json.
-	config, err := google.ConfigFromJSON(b, "https://www.googleapis.com/auth/spreadsheets")
-	if err != nil {
-		return nil, err
-	}
-	tok, err := getOauthToken(ctx, config)
-	if err != nil {
-		return nil, err
-	}
-	return sheets.New(config.Client(ctx, tok))
-}
-
-func getOauthToken(ctx context.Context, config *oauth2.Config) (*oauth2.Token, error) {
-	// token.json stores the user's access and refresh tokens, and is created
-	// automatically when the authorization flow completes for the first time.
-	f, err := os.Open(*tokenFile)
-	if err == nil {
-		defer f.Close()
-		tok := &oauth2.Token{}
-		if err := json.NewDecoder(f).Decode(tok); err != nil {
-			return nil, err
+// firstNonEmpty returns the first of vals that isn't the empty string, or
+// "" if they all are.
+func firstNonEmpty(vals ...string) string {
+	for _, v := range vals {
+		if v != "" {
+			return v
 		}
-		return tok, nil
-	}
-	if !os.IsNotExist(err) {
-		return nil, err
-	}
-	// If the token file isn't available, create one.
-	// Request a token from the web, then returns the retrieved token.
-	authURL := config.AuthCodeURL("state-token", oauth2.AccessTypeOffline)
-	fmt.Printf("Go to the following link in your browser then type the "+
-		"authorization code: \n%v\n", authURL)
-
-	var authCode string
-	if _, err := fmt.Scan(&authCode); err != nil {
-		return nil, err
-	}
-	tok, err := config.Exchange(ctx, authCode)
-	if err != nil {
-		return nil, err
 	}
-	// Save the token for future use.
-	fmt.Printf("Saving credential file to: %s\n", *tokenFile)
-	f, err = os.OpenFile(*tokenFile, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
-	if err != nil {
-		return nil, err
-	}
-	defer f.Close()
-
-	if err := json.NewEncoder(f).Encode(tok); err != nil {
-		return nil, err
-	}
-	return tok, nil
+	return ""
 }
 
 func createSheet(ctx context.Context, srv *sheets.Service, start time.Time, rowData map[string][]*sheets.RowData) (*sheets.Spreadsheet, error) {
@@ -323,46 +320,3 @@ func createSheet(ctx context.Context, srv *sheets.Service, start time.Time, rowD
 	}
 	return srv.Spreadsheets.Create(spreadsheet).Context(ctx).Do()
 }
-
-func appendToSheet(ctx context.Context, srv *sheets.Service, spreadsheetID string, rowData map[string][]*sheets.RowData) (*sheets.Spreadsheet, error) {
-	// First, create the new sheets in spreadsheet.
-	var createRequests []*sheets.Request
-	for title := range rowData {
-		createRequests = append(createRequests, &sheets.Request{
-			AddSheet: &sheets.AddSheetRequest{
-				Properties: &sheets.SheetProperties{
-					Title: title,
-					GridProperties: &sheets.GridProperties{
-						FrozenRowCount: 1,
-					},
-				},
-			},
-		})
-	}
-	response, err := srv.Spreadsheets.BatchUpdate(spreadsheetID, &sheets.BatchUpdateSpreadsheetRequest{
-		IncludeSpreadsheetInResponse: true,
-		Requests:                     createRequests,
-	}).Context(ctx).Do()
-	if err != nil {
-		return nil, err
-	}
-	// Now, add the data to the spreadsheets.
-	var dataRequests []*sheets.Request
-	for _, sheet := range response.UpdatedSpreadsheet.Sheets {
-		dataRequests = append(dataRequests, &sheets.Request{
-			AppendCells: &sheets.AppendCellsRequest{
-				SheetId: sheet.Properties.SheetId,
-				Rows:    rowData[sheet.Properties.Title],
-				Fields:  "*",
-			},
-		})
-	}
-	response, err = srv.Spreadsheets.BatchUpdate(spreadsheetID, &sheets.BatchUpdateSpreadsheetRequest{
-		IncludeSpreadsheetInResponse: true,
-		Requests:                     dataRequests,
-	}).Context(ctx).Do()
-	if err != nil {
-		return nil, err
-	}
-	return response.UpdatedSpreadsheet, nil
-}
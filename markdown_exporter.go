@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// markdownExporter writes each sheet to its own "<title>.md" file in dir,
+// as a GitHub-flavored Markdown table. Header, total, and subtotal rows
+// are bolded to mirror the Sheets exporter's styling.
+type markdownExporter struct {
+	dir     string
+	file    *os.File
+	path    string
+	ncols   int
+	started bool
+}
+
+func newMarkdownExporter(dir string) *markdownExporter {
+	return &markdownExporter{dir: dir}
+}
+
+func (e *markdownExporter) Begin(title string) error {
+	e.path = filepath.Join(e.dir, fmt.Sprintf("%s.md", title))
+	file, err := os.Create(e.path)
+	if err != nil {
+		return err
+	}
+	e.file = file
+	e.started = false
+	return nil
+}
+
+func (e *markdownExporter) Row(cells []string, style RowStyle) error {
+	out := make([]string, len(cells))
+	for i, cell := range cells {
+		cell = strings.ReplaceAll(cell, "|", "\\|")
+		if style != RowNormal {
+			cell = fmt.Sprintf("**%s**", cell)
+		}
+		out[i] = cell
+	}
+	if _, err := fmt.Fprintf(e.file, "| %s |\n", strings.Join(out, " | ")); err != nil {
+		return err
+	}
+	if !e.started {
+		e.ncols = len(cells)
+		sep := make([]string, e.ncols)
+		for i := range sep {
+			sep[i] = "---"
+		}
+		if _, err := fmt.Fprintf(e.file, "| %s |\n", strings.Join(sep, " | ")); err != nil {
+			return err
+		}
+		e.started = true
+	}
+	return nil
+}
+
+func (e *markdownExporter) End() error {
+	if err := e.file.Close(); err != nil {
+		return err
+	}
+	fmt.Printf("Wrote output to %s.\n", e.path)
+	return nil
+}
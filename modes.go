@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/stamblerre/work-stats/github"
+	"github.com/stamblerre/work-stats/golang"
+	"golang.org/x/build/maintner"
+)
+
+// modeParams carries the inputs available to every Mode's Run method.
+type modeParams struct {
+	Corpus       *maintner.Corpus
+	Contributors []contributor
+	Start, Until time.Time // Until is the zero Time if there's no upper bound.
+	Projects     []string  // restricts Gerrit analyses; empty means all projects.
+}
+
+// Mode is a single pluggable analysis selectable by name via the -mode
+// flag. New analyses can be added by implementing this interface and
+// registering an instance with registerMode, without touching main.
+type Mode interface {
+	Name() string
+	Description() string
+	Run(ctx context.Context, p *modeParams) (map[string][][]string, error)
+}
+
+// modes holds every registered analysis, keyed by its -mode name.
+var modes = map[string]Mode{}
+
+func registerMode(m Mode) {
+	modes[m.Name()] = m
+}
+
+func init() {
+	registerMode(clStatsMode{})
+	registerMode(issueCloseStatsMode{})
+	registerMode(reviewLatencyMode{})
+	registerMode(firstResponseTimeMode{})
+	registerMode(contributorWorkshopMode{})
+}
+
+// modeNames returns the names of every registered mode, sorted, for use in
+// flag usage strings and error messages.
+func modeNames() []string {
+	var names []string
+	for name := range modes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// clStatsMode reports Gerrit CL counts per contributor, restricted to
+// -projects if given.
+type clStatsMode struct{}
+
+func (clStatsMode) Name() string        { return "cl-stats" }
+func (clStatsMode) Description() string { return "Gerrit CL counts per contributor" }
+func (clStatsMode) Run(ctx context.Context, p *modeParams) (map[string][][]string, error) {
+	if p.Corpus == nil {
+		return nil, fmt.Errorf("cl-stats requires Gerrit data; rerun without -gerrit=false")
+	}
+	data := make(map[string][][]string)
+	for _, c := range p.Contributors {
+		cls, err := golang.Changelists(p.Corpus.Gerrit(), c.Emails, p.Start, p.Until, p.Projects)
+		if err != nil {
+			return nil, err
+		}
+		for title, rows := range cls {
+			data[fmt.Sprintf("%s: %s", c.Username, title)] = rows
+		}
+	}
+	return data, nil
+}
+
+// issueCloseStatsMode reports open vs. closed issue counts per contributor,
+// across golang/go and the rest of GitHub.
+type issueCloseStatsMode struct{}
+
+func (issueCloseStatsMode) Name() string { return "issue-close-stats" }
+func (issueCloseStatsMode) Description() string {
+	return "open vs. closed issue counts per contributor"
+}
+func (issueCloseStatsMode) Run(ctx context.Context, p *modeParams) (map[string][][]string, error) {
+	data := make(map[string][][]string)
+	for _, c := range p.Contributors {
+		var open, closed int
+		tally := func(sheets map[string][][]string) {
+			for _, rows := range sheets {
+				for _, row := range rows[1:] {
+					if len(row) == 0 {
+						continue
+					}
+					switch row[0] {
+					case "Open":
+						open++
+					case "Closed":
+						closed++
+					}
+				}
+			}
+		}
+		if p.Corpus != nil {
+			goIssues, err := golang.Issues(p.Corpus.GitHub(), c.Username, p.Start, p.Until)
+			if err != nil {
+				return nil, err
+			}
+			tally(goIssues)
+		}
+		githubIssues, err := github.IssuesAndPRs(ctx, c.Username, p.Start, p.Until)
+		if err != nil {
+			return nil, err
+		}
+		tally(githubIssues)
+
+		data[fmt.Sprintf("%s: Issue Close Stats", c.Username)] = [][]string{
+			{"Status", "Count"},
+			{"Open", fmt.Sprintf("%d", open)},
+			{"Closed", fmt.Sprintf("%d", closed)},
+			{"Total", fmt.Sprintf("%d", open+closed)},
+		}
+	}
+	return data, nil
+}
+
+// reviewLatencyMode reports, per contributor, the average time to first
+// human review, to first Code-Review+2, and to submit for their CLs,
+// bucketed by week and by Gerrit project.
+type reviewLatencyMode struct{}
+
+func (reviewLatencyMode) Name() string { return "review-latency" }
+func (reviewLatencyMode) Description() string {
+	return "weekly review latency (first review, first +2, submit) per contributor"
+}
+func (reviewLatencyMode) Run(ctx context.Context, p *modeParams) (map[string][][]string, error) {
+	if p.Corpus == nil {
+		return nil, fmt.Errorf("review-latency requires Gerrit data; rerun without -gerrit=false")
+	}
+	data := make(map[string][][]string)
+	for _, c := range p.Contributors {
+		latency, err := golang.ReviewLatency(p.Corpus.Gerrit(), c.Emails, p.Start, p.Until, p.Projects)
+		if err != nil {
+			return nil, err
+		}
+		for title, rows := range latency {
+			data[fmt.Sprintf("%s: %s", c.Username, title)] = rows
+		}
+	}
+	return data, nil
+}
+
+// firstResponseTimeMode reports, per contributor, the median and 95th
+// percentile time to first response across every GitHub issue they opened
+// or were assigned to.
+type firstResponseTimeMode struct{}
+
+func (firstResponseTimeMode) Name() string { return "first-response-time" }
+func (firstResponseTimeMode) Description() string {
+	return "median/95th percentile time to first response on GitHub issues"
+}
+func (firstResponseTimeMode) Run(ctx context.Context, p *modeParams) (map[string][][]string, error) {
+	data := make(map[string][][]string)
+	for _, c := range p.Contributors {
+		responseTime, err := github.FirstResponseTime(ctx, c.Username, p.Start, p.Until)
+		if err != nil {
+			return nil, err
+		}
+		for title, rows := range responseTime {
+			data[fmt.Sprintf("%s: %s", c.Username, title)] = rows
+		}
+	}
+	return data, nil
+}
+
+// contributorWorkshopMode reports per-contributor subtotals and team grand
+// totals across every category of data, for workshops comparing CL counts,
+// review load, and issue triage between team members.
+type contributorWorkshopMode struct{}
+
+func (contributorWorkshopMode) Name() string { return "contributor-workshop" }
+func (contributorWorkshopMode) Description() string {
+	return "per-contributor subtotals and team grand totals"
+}
+func (contributorWorkshopMode) Run(ctx context.Context, p *modeParams) (map[string][][]string, error) {
+	r := newRollup()
+	for _, c := range p.Contributors {
+		data, err := collect(ctx, p.Corpus, c, p.Start, p.Until, p.Projects)
+		if err != nil {
+			return nil, err
+		}
+		for title, rows := range data {
+			r.add(c.Username, title, rows)
+		}
+	}
+	return r.sheet(), nil
+}
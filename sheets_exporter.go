@@ -0,0 +1,55 @@
+package main
+
+import (
+	"google.golang.org/api/sheets/v4"
+)
+
+// sheetsExporter builds the rowData that createSheet and appendToSheet
+// send to the Google Sheets API. It doesn't talk to the API itself: main
+// hands the accumulated rowData to whichever of those two it needs once
+// every other exporter has also run.
+type sheetsExporter struct {
+	rowData map[string][]*sheets.RowData
+	title   string
+	rows    []*sheets.RowData
+}
+
+func newSheetsExporter(rowData map[string][]*sheets.RowData) *sheetsExporter {
+	return &sheetsExporter{rowData: rowData}
+}
+
+func (e *sheetsExporter) Begin(title string) error {
+	e.title = title
+	e.rows = nil
+	return nil
+}
+
+func (e *sheetsExporter) Row(cells []string, style RowStyle) error {
+	var values []*sheets.CellData
+	for _, cell := range cells {
+		cd := &sheets.CellData{
+			UserEnteredValue: &sheets.ExtendedValue{
+				StringValue: cell,
+			},
+			UserEnteredFormat: &sheets.CellFormat{
+				TextFormat: &sheets.TextFormat{
+					Bold: style != RowNormal,
+				},
+			},
+		}
+		switch style {
+		case RowSubtotal:
+			cd.UserEnteredFormat.BackgroundColor = &sheets.Color{Blue: 0.96, Green: 0.96, Red: 0.96}
+		case RowTotal:
+			cd.UserEnteredFormat.BackgroundColor = &sheets.Color{Blue: 0.92, Green: 0.92, Red: 0.92}
+		}
+		values = append(values, cd)
+	}
+	e.rows = append(e.rows, &sheets.RowData{Values: values})
+	return nil
+}
+
+func (e *sheetsExporter) End() error {
+	e.rowData[e.title] = e.rows
+	return nil
+}